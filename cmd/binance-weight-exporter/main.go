@@ -0,0 +1,200 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/procyon-projects/chrono"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	binanceweight "github.com/frysee/binance-weight-exporter"
+)
+
+var (
+	taskScheduler = chrono.NewDefaultTaskScheduler()
+
+	listenAddress = flag.String("web.listen-address", ":9133",
+		"Address to listen on for telemetry")
+	metricsPath = flag.String("web.telemetry-path", "/metrics",
+		"Path under which to expose metrics")
+	autoScrape = flag.Bool("auto-scrape", false,
+		"Poll targets on a fixed one-minute schedule instead of on every /metrics scrape")
+	configPath = flag.String("config", "",
+		"Path to a YAML config file with a top-level targets: list of name/type/url entries. Takes precedence over -target.")
+	passive = flag.Bool("passive", false,
+		"Disable self-polling entirely; only report weight observed via a WeightTransport wired in by an embedding program")
+
+	apiKey = flag.String("api-key", os.Getenv("BINANCE_API_KEY"),
+		"Binance API key, used for signed polling. Defaults to $BINANCE_API_KEY")
+	apiSecret = flag.String("api-secret", os.Getenv("BINANCE_API_SECRET"),
+		"Binance API secret, used for signed polling. Defaults to $BINANCE_API_SECRET")
+	signedInterval = flag.Duration("signed-interval", time.Minute,
+		"How often to probe the signed account endpoint when -api-key/-api-secret are set")
+
+	ws = flag.Bool("ws", false,
+		"Open a market-data WebSocket per target for binance_ws_* health metrics, in addition to REST weight polling")
+	wsStreams = flag.String("ws-streams", "",
+		"Comma-separated public stream names to subscribe to in -ws mode, e.g. btcusdt@trade")
+
+	targets []binanceweight.Target
+)
+
+// resolveTargets returns the configured targets: -config file if given,
+// otherwise the repeated -target flags, otherwise a single default spot
+// target equivalent to this exporter's original hard-coded behaviour.
+func resolveTargets() ([]binanceweight.Target, error) {
+	if *configPath != "" {
+		cfg, err := binanceweight.LoadConfig(*configPath)
+		if err != nil {
+			return nil, err
+		}
+		return cfg.Targets, nil
+	}
+
+	if len(targets) > 0 {
+		return targets, nil
+	}
+
+	return []binanceweight.Target{{Name: "spot", Type: binanceweight.SpotMarket}}, nil
+}
+
+// splitStreams turns a comma-separated -ws-streams flag value into a clean
+// slice, dropping empty entries.
+func splitStreams(value string) []string {
+	var streams []string
+	for _, s := range strings.Split(value, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			streams = append(streams, s)
+		}
+	}
+	return streams
+}
+
+// newInstrumentedHandler wraps handler with the promhttp middleware family
+// (in-flight gauge, request counter, request duration), registering the
+// gauge against reg and labeling the counter/duration with handlerName so
+// multiple handlers can share them.
+func newInstrumentedHandler(reg *prometheus.Registry, handlerName string, handler http.Handler, counter *prometheus.CounterVec, duration *prometheus.HistogramVec) http.Handler {
+	inFlight := prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace:   "binance_exporter",
+		Name:        "in_flight_requests",
+		Help:        "Current number of in-flight requests to the exporter's own HTTP server.",
+		ConstLabels: prometheus.Labels{"handler": handlerName},
+	})
+	reg.MustRegister(inFlight)
+
+	return promhttp.InstrumentHandlerInFlight(inFlight,
+		promhttp.InstrumentHandlerDuration(duration.MustCurryWith(prometheus.Labels{"handler": handlerName}),
+			promhttp.InstrumentHandlerCounter(counter.MustCurryWith(prometheus.Labels{"handler": handlerName}), handler)))
+}
+
+func main() {
+	flag.Var(&binanceweight.TargetFlag{Targets: &targets}, "target",
+		"Repeatable. A target to scrape: name=<market label>,type=spot|umfutures|cmfutures,url=<override>")
+	flag.Parse()
+
+	resolved, err := resolveTargets()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	selfPoll := !*autoScrape && !*passive
+
+	// A private registry, rather than prometheus.MustRegister on the global
+	// default one, so this binary's metrics don't leak into the default
+	// registry when binanceweight is embedded as a library alongside it.
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(collectors.NewGoCollector())
+	reg.MustRegister(collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+
+	for _, target := range resolved {
+		exporter, err := binanceweight.NewCollector(target, selfPoll)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if err := exporter.FetchRateLimits(); err != nil {
+			log.Printf("%s: failed to fetch rate limits from exchangeInfo: %v", target.Name, err)
+		}
+		reg.MustRegister(exporter)
+
+		// Check API every minute at second 58
+		if *autoScrape && !*passive {
+			now := time.Now()
+			_, err := taskScheduler.ScheduleAtFixedRate(func(ctx context.Context) {
+				exporter.RequestWeight()
+			}, 1*time.Minute, chrono.WithStartTime(now.Year(), now.Month(), now.Day(), now.Hour(), now.Minute(), 58))
+
+			if err == nil {
+				log.Printf("%s: started schedule.", target.Name)
+			}
+		}
+
+		if *apiKey != "" && *apiSecret != "" && !*passive {
+			_, err := taskScheduler.ScheduleAtFixedRate(func(ctx context.Context) {
+				if err := exporter.FetchSignedWeight(*apiKey, *apiSecret); err != nil {
+					log.Printf("%s: signed poll failed: %v", target.Name, err)
+				}
+			}, *signedInterval)
+
+			if err == nil {
+				log.Printf("%s: started signed poll schedule.", target.Name)
+			}
+		}
+
+		if *ws {
+			wsExporter, err := binanceweight.NewWSCollector(target, *apiKey, splitStreams(*wsStreams))
+			if err != nil {
+				log.Fatal(err)
+			}
+			reg.MustRegister(wsExporter)
+
+			go func(target binanceweight.Target) {
+				if err := wsExporter.Run(context.Background()); err != nil {
+					log.Printf("%s: websocket stream stopped: %v", target.Name, err)
+				}
+			}(target)
+		}
+	}
+
+	httpRequestsTotal := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "binance_exporter",
+			Name:      "http_requests_total",
+			Help:      "Total HTTP requests to the exporter's own HTTP server, by handler.",
+		},
+		[]string{"handler", "code", "method"},
+	)
+	httpRequestDuration := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "binance_exporter",
+			Name:      "http_request_duration_seconds",
+			Help:      "Latency of HTTP requests to the exporter's own HTTP server, by handler.",
+			Buckets:   prometheus.DefBuckets,
+		},
+		[]string{"handler", "method"},
+	)
+	reg.MustRegister(httpRequestsTotal, httpRequestDuration)
+
+	indexHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<html>
+             <head><title>Binance API Weight Exporter</title></head>
+             <body>
+             <h1>Binance API Weight Exporter</h1>
+             <p><a href='` + *metricsPath + `'>Metrics</a></p>
+             </body>
+             </html>`))
+	})
+
+	http.Handle(*metricsPath, newInstrumentedHandler(reg, "metrics",
+		promhttp.HandlerFor(reg, promhttp.HandlerOpts{}), httpRequestsTotal, httpRequestDuration))
+	http.Handle("/", newInstrumentedHandler(reg, "index", indexHandler, httpRequestsTotal, httpRequestDuration))
+	log.Fatal(http.ListenAndServe(*listenAddress, nil))
+}
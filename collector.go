@@ -0,0 +1,363 @@
+// Package binanceweight collects Binance API rate-limit usage
+// (X-MBX-USED-WEIGHT-*, X-MBX-ORDER-COUNT-*) as Prometheus metrics. It can
+// be run standalone via cmd/binance-weight-exporter, or embedded as a
+// library: wrap an http.Client's Transport in a WeightTransport to have
+// every request your program already makes feed the same metrics.
+package binanceweight
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const namespace = "binance"
+
+// usedWeightHeader matches headers like `X-MBX-USED-WEIGHT-1M` and captures
+// the interval number and unit letter.
+var usedWeightHeader = regexp.MustCompile(`(?i)^X-Mbx-Used-Weight-(\d+)([a-z])$`)
+
+// orderCountHeader matches headers like `X-MBX-ORDER-COUNT-10S` and captures
+// the interval number and unit letter.
+var orderCountHeader = regexp.MustCompile(`(?i)^X-Mbx-Order-Count-(\d+)([a-z])$`)
+
+// Define a struct for you collector that contains pointers
+// to prometheus descriptors for each metric you wish to expose.
+// Note you can also include fields of other types if they provide utility
+// but we just won't be exposing them as metrics.
+type WeightCollector struct {
+	up               *prometheus.Desc
+	weightUsed       *prometheus.Desc
+	orderCountUsed   *prometheus.Desc
+	weightLimit      *prometheus.Desc
+	lastStatusCode   *prometheus.Desc
+	rateLimitedTotal *prometheus.Desc
+	bannedTotal      *prometheus.Desc
+	retryAfter       *prometheus.Desc
+	banUntil         *prometheus.Desc
+
+	market           string
+	binanceEndpoint  string
+	pingPath         string
+	exchangeInfoPath string
+	accountPath      string
+
+	// selfPoll, when true, makes Collect synthesize a cheap request on every
+	// scrape to refresh the headers below. Set it to false when metrics are
+	// fed exclusively via a WeightTransport (see transport.go) or a
+	// caller-managed schedule (e.g. FetchSignedWeight on a timer).
+	selfPoll bool
+
+	mu                    sync.Mutex
+	weightByInterval      map[string]float64
+	orderCountByInterval  map[string]float64
+	weightLimitByInterval map[string]float64
+	isUp                  float64
+	lastStatus            float64
+	rateLimitedCount      float64
+	bannedCount           float64
+	retryAfterSeconds     float64
+	banUntilTimestamp     float64
+	backoffUntil          time.Time
+}
+
+// exchangeInfoResponse is the subset of `/api/v3/exchangeInfo` we care about.
+type exchangeInfoResponse struct {
+	RateLimits []rateLimit `json:"rateLimits"`
+}
+
+// rateLimit mirrors one entry of the `rateLimits` array returned by
+// `/api/v3/exchangeInfo`.
+type rateLimit struct {
+	RateLimitType string  `json:"rateLimitType"`
+	Interval      string  `json:"interval"`
+	IntervalNum   int     `json:"intervalNum"`
+	Limit         float64 `json:"limit"`
+}
+
+var client = &http.Client{}
+
+// NewCollector builds a WeightCollector for the given target. selfPoll
+// controls whether Collect probes the target itself on every scrape (the
+// original behaviour) or only reports whatever headers have been recorded
+// via parseHeaders, e.g. through a WeightTransport or FetchSignedWeight.
+func NewCollector(target Target, selfPoll bool) (*WeightCollector, error) {
+	paths, ok := defaultMarketPaths[target.Type]
+	if !ok {
+		return nil, fmt.Errorf("target %q: unknown type %q", target.Name, target.Type)
+	}
+
+	binanceEndpoint := target.URL
+	if binanceEndpoint == "" {
+		binanceEndpoint = paths.endpoint
+	}
+
+	return &WeightCollector{
+		market:                target.Name,
+		binanceEndpoint:       binanceEndpoint,
+		pingPath:              paths.ping,
+		exchangeInfoPath:      paths.exchangeInfo,
+		accountPath:           paths.account,
+		selfPoll:              selfPoll,
+		weightByInterval:      map[string]float64{},
+		orderCountByInterval:  map[string]float64{},
+		weightLimitByInterval: map[string]float64{},
+		isUp:                  0,
+		up: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "up"),
+			"Was the last Binance API query successful.",
+			[]string{"market"}, nil,
+		),
+		// Headers `X-MBX-USED-WEIGHT-(intervalNum)(intervalLetter)` will give your current used
+		// request weight for the (intervalNum)(intervalLetter) rate limiter.
+		// For example, if there is a one minute request rate weight limiter set, you will get a
+		// `X-MBX-USED-WEIGHT-1M` header in the response. The legacy header `X-MBX-USED-WEIGHT`
+		// will still be returned and will represent the current used weight for the one minute
+		// request rate weight limit.
+		weightUsed: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "weight_used"),
+			"Weight used (X-MBX-USED-WEIGHT-*), labeled by market and rate limit interval.",
+			[]string{"market", "interval"}, nil,
+		),
+		// Headers `X-MBX-ORDER-COUNT-(intervalNum)(intervalLetter)` give the current used
+		// order count for the ORDERS rate limiter over that interval.
+		orderCountUsed: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "order_count_used"),
+			"Order count used (X-MBX-ORDER-COUNT-*), labeled by market and rate limit interval.",
+			[]string{"market", "interval"}, nil,
+		),
+		// Populated once at startup from the REQUEST_WEIGHT entries of
+		// `/api/v3/exchangeInfo`'s `rateLimits` array.
+		weightLimit: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "weight_limit"),
+			"Configured request weight limit per market and interval, from exchangeInfo rateLimits.",
+			[]string{"market", "interval"}, nil,
+		),
+		lastStatusCode: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "last_status_code"),
+			"HTTP status code of the last probe.",
+			[]string{"market"}, nil,
+		),
+		rateLimitedTotal: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "rate_limited_total"),
+			"Number of probes that received HTTP 429 (Too Many Requests).",
+			[]string{"market"}, nil,
+		),
+		bannedTotal: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "banned_total"),
+			"Number of probes that received HTTP 418 (IP banned).",
+			[]string{"market"}, nil,
+		),
+		retryAfter: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "retry_after_seconds"),
+			"Retry-After value of the most recent 429/418 response, in seconds.",
+			[]string{"market"}, nil,
+		),
+		banUntil: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "ban_until_timestamp"),
+			"Unix timestamp until which this target is believed to be banned (HTTP 418), from the last Retry-After.",
+			[]string{"market"}, nil,
+		),
+	}, nil
+}
+
+func (c *WeightCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.up
+	ch <- c.weightUsed
+	ch <- c.orderCountUsed
+	ch <- c.weightLimit
+	ch <- c.lastStatusCode
+	ch <- c.rateLimitedTotal
+	ch <- c.bannedTotal
+	ch <- c.retryAfter
+	ch <- c.banUntil
+}
+
+func (c *WeightCollector) Collect(ch chan<- prometheus.Metric) {
+	if c.selfPoll {
+		c.RequestWeight()
+	}
+	c.UpdateMetrics(ch)
+}
+
+// FetchRateLimits calls the target's exchangeInfo endpoint once and records
+// the REQUEST_WEIGHT entries of its `rateLimits` array as limit gauges, keyed
+// by the same interval label used for the `X-MBX-USED-WEIGHT-*` headers
+// (e.g. "1m", "10s").
+func (c *WeightCollector) FetchRateLimits() error {
+	req, err := http.NewRequest("GET", c.binanceEndpoint+c.exchangeInfoPath, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var info exchangeInfoResponse
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, rl := range info.RateLimits {
+		if rl.RateLimitType != "REQUEST_WEIGHT" {
+			continue
+		}
+		interval, err := intervalLabel(rl.Interval, rl.IntervalNum)
+		if err != nil {
+			log.Printf("%s: skipping rate limit with unknown interval %q: %v", c.market, rl.Interval, err)
+			continue
+		}
+		c.weightLimitByInterval[interval] = rl.Limit
+	}
+
+	return nil
+}
+
+// intervalLabel converts an exchangeInfo interval ("SECOND", "MINUTE",
+// "HOUR", "DAY") and intervalNum into the same label format used for the
+// `X-MBX-USED-WEIGHT-{intervalNum}{intervalLetter}` headers, e.g. "1m", "10s".
+func intervalLabel(interval string, intervalNum int) (string, error) {
+	var letter string
+	switch strings.ToUpper(interval) {
+	case "SECOND":
+		letter = "s"
+	case "MINUTE":
+		letter = "m"
+	case "HOUR":
+		letter = "h"
+	case "DAY":
+		letter = "d"
+	default:
+		return "", fmt.Errorf("unknown interval %q", interval)
+	}
+	return fmt.Sprintf("%d%s", intervalNum, letter), nil
+}
+
+// RequestWeight issues a cheap synthetic request against the target purely
+// to read its rate-limit headers off the response.
+func (c *WeightCollector) RequestWeight() {
+	if wait, until := c.backoff(); wait {
+		log.Printf("%s: skipping probe, backing off until %s", c.market, until)
+		return
+	}
+
+	req, err := http.NewRequest("GET", c.binanceEndpoint+c.pingPath, nil)
+	if err != nil {
+		log.Printf("%s: building probe request: %v", c.market, err)
+		c.mu.Lock()
+		c.isUp = 0
+		c.mu.Unlock()
+		return
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		log.Print(err)
+		c.mu.Lock()
+		c.isUp = 0
+		c.mu.Unlock()
+		return
+	}
+	defer resp.Body.Close()
+
+	c.mu.Lock()
+	c.isUp = 1
+	c.mu.Unlock()
+	c.observeResponse(resp)
+}
+
+// parseHeaders scans all response headers for `X-MBX-USED-WEIGHT-*` and
+// `X-MBX-ORDER-COUNT-*` entries and records one value per interval, so that
+// limiters other than the legacy one-minute window are also captured. It is
+// safe to call concurrently, which lets a WeightTransport feed it from
+// whatever goroutines are making real requests.
+func (c *WeightCollector) parseHeaders(header http.Header) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for name, values := range header {
+		if len(values) == 0 {
+			continue
+		}
+		value, err := strconv.ParseFloat(values[0], 64)
+		if err != nil {
+			continue
+		}
+
+		if m := usedWeightHeader.FindStringSubmatch(name); m != nil {
+			c.weightByInterval[m[1]+strings.ToLower(m[2])] = value
+			continue
+		}
+
+		if m := orderCountHeader.FindStringSubmatch(name); m != nil {
+			c.orderCountByInterval[m[1]+strings.ToLower(m[2])] = value
+			continue
+		}
+	}
+}
+
+func (c *WeightCollector) UpdateMetrics(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ch <- prometheus.MustNewConstMetric(
+		c.lastStatusCode, prometheus.GaugeValue, c.lastStatus, c.market,
+	)
+	ch <- prometheus.MustNewConstMetric(
+		c.rateLimitedTotal, prometheus.CounterValue, c.rateLimitedCount, c.market,
+	)
+	ch <- prometheus.MustNewConstMetric(
+		c.bannedTotal, prometheus.CounterValue, c.bannedCount, c.market,
+	)
+	ch <- prometheus.MustNewConstMetric(
+		c.retryAfter, prometheus.GaugeValue, c.retryAfterSeconds, c.market,
+	)
+	ch <- prometheus.MustNewConstMetric(
+		c.banUntil, prometheus.GaugeValue, c.banUntilTimestamp, c.market,
+	)
+
+	// These reflect the last successfully observed values and are useful
+	// even when the current probe failed to connect, so they're reported
+	// unconditionally rather than being gated on isUp.
+	for interval, value := range c.weightByInterval {
+		ch <- prometheus.MustNewConstMetric(
+			c.weightUsed, prometheus.GaugeValue, value, c.market, interval,
+		)
+	}
+
+	for interval, value := range c.orderCountByInterval {
+		ch <- prometheus.MustNewConstMetric(
+			c.orderCountUsed, prometheus.GaugeValue, value, c.market, interval,
+		)
+	}
+
+	for interval, limit := range c.weightLimitByInterval {
+		ch <- prometheus.MustNewConstMetric(
+			c.weightLimit, prometheus.GaugeValue, limit, c.market, interval,
+		)
+	}
+
+	if c.isUp == 0 {
+		ch <- prometheus.MustNewConstMetric(
+			c.up, prometheus.GaugeValue, 0, c.market,
+		)
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		c.up, prometheus.GaugeValue, 1, c.market,
+	)
+}
@@ -0,0 +1,110 @@
+package binanceweight
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestIntervalLabel(t *testing.T) {
+	tests := []struct {
+		interval    string
+		intervalNum int
+		want        string
+		wantErr     bool
+	}{
+		{"SECOND", 10, "10s", false},
+		{"MINUTE", 1, "1m", false},
+		{"HOUR", 1, "1h", false},
+		{"DAY", 1, "1d", false},
+		{"minute", 5, "5m", false},
+		{"WEEK", 1, "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := intervalLabel(tt.interval, tt.intervalNum)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("intervalLabel(%q, %d): expected error, got %q", tt.interval, tt.intervalNum, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("intervalLabel(%q, %d): unexpected error: %v", tt.interval, tt.intervalNum, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("intervalLabel(%q, %d) = %q, want %q", tt.interval, tt.intervalNum, got, tt.want)
+		}
+	}
+}
+
+func TestUsedWeightHeader(t *testing.T) {
+	tests := []struct {
+		header  string
+		matches bool
+		num     string
+		letter  string
+	}{
+		{"X-MBX-USED-WEIGHT-1M", true, "1", "M"},
+		{"x-mbx-used-weight-10s", true, "10", "s"},
+		{"X-MBX-USED-WEIGHT", false, "", ""},
+		{"X-MBX-ORDER-COUNT-1M", false, "", ""},
+	}
+
+	for _, tt := range tests {
+		m := usedWeightHeader.FindStringSubmatch(tt.header)
+		if tt.matches != (m != nil) {
+			t.Errorf("usedWeightHeader.MatchString(%q) matched = %v, want %v", tt.header, m != nil, tt.matches)
+			continue
+		}
+		if m != nil && (m[1] != tt.num || m[2] != tt.letter) {
+			t.Errorf("usedWeightHeader.FindStringSubmatch(%q) = %v, want num=%q letter=%q", tt.header, m, tt.num, tt.letter)
+		}
+	}
+}
+
+func TestOrderCountHeader(t *testing.T) {
+	tests := []struct {
+		header  string
+		matches bool
+		num     string
+		letter  string
+	}{
+		{"X-MBX-ORDER-COUNT-10S", true, "10", "S"},
+		{"x-mbx-order-count-1d", true, "1", "d"},
+		{"X-MBX-ORDER-COUNT", false, "", ""},
+		{"X-MBX-USED-WEIGHT-10S", false, "", ""},
+	}
+
+	for _, tt := range tests {
+		m := orderCountHeader.FindStringSubmatch(tt.header)
+		if tt.matches != (m != nil) {
+			t.Errorf("orderCountHeader.MatchString(%q) matched = %v, want %v", tt.header, m != nil, tt.matches)
+			continue
+		}
+		if m != nil && (m[1] != tt.num || m[2] != tt.letter) {
+			t.Errorf("orderCountHeader.FindStringSubmatch(%q) = %v, want num=%q letter=%q", tt.header, m, tt.num, tt.letter)
+		}
+	}
+}
+
+func TestParseHeaders(t *testing.T) {
+	c, err := NewCollector(Target{Name: "spot", Type: SpotMarket}, false)
+	if err != nil {
+		t.Fatalf("NewCollector: %v", err)
+	}
+
+	header := http.Header{}
+	header.Set("X-MBX-USED-WEIGHT-1M", "42")
+	header.Set("X-MBX-ORDER-COUNT-10S", "3")
+	header.Set("Content-Type", "application/json")
+
+	c.parseHeaders(header)
+
+	if got := c.weightByInterval["1m"]; got != 42 {
+		t.Errorf("weightByInterval[1m] = %v, want 42", got)
+	}
+	if got := c.orderCountByInterval["10s"]; got != 3 {
+		t.Errorf("orderCountByInterval[10s] = %v, want 3", got)
+	}
+}
@@ -0,0 +1,131 @@
+package binanceweight
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// marketType identifies which Binance API family a target talks to. Each has
+// its own endpoint, REST path prefix and independent rate-limit budget.
+type marketType string
+
+const (
+	SpotMarket      marketType = "spot"
+	UMFuturesMarket marketType = "umfutures"
+	CMFuturesMarket marketType = "cmfutures"
+)
+
+// marketPaths holds the default endpoint and the REST paths used to probe
+// and sign requests for a given marketType.
+type marketPaths struct {
+	endpoint       string
+	ping           string
+	exchangeInfo   string
+	account        string
+	userDataStream string
+	wsEndpoint     string
+}
+
+var defaultMarketPaths = map[marketType]marketPaths{
+	SpotMarket: {
+		endpoint:       "https://api.binance.com",
+		ping:           "/api/v3/ping",
+		exchangeInfo:   "/api/v3/exchangeInfo",
+		account:        "/api/v3/account",
+		userDataStream: "/api/v3/userDataStream",
+		wsEndpoint:     "wss://stream.binance.com:9443",
+	},
+	UMFuturesMarket: {
+		endpoint:       "https://fapi.binance.com",
+		ping:           "/fapi/v1/ping",
+		exchangeInfo:   "/fapi/v1/exchangeInfo",
+		account:        "/fapi/v2/account",
+		userDataStream: "/fapi/v1/listenKey",
+		wsEndpoint:     "wss://fstream.binance.com",
+	},
+	CMFuturesMarket: {
+		endpoint:       "https://dapi.binance.com",
+		ping:           "/dapi/v1/ping",
+		exchangeInfo:   "/dapi/v1/exchangeInfo",
+		account:        "/dapi/v1/account",
+		userDataStream: "/dapi/v1/listenKey",
+		wsEndpoint:     "wss://dstream.binance.com",
+	},
+}
+
+// Target describes a single Binance API to scrape: a name (exposed as the
+// `market` label), which API family it is, and optionally a URL override
+// (defaults to the family's public endpoint).
+type Target struct {
+	Name string     `yaml:"name"`
+	Type marketType `yaml:"type"`
+	URL  string     `yaml:"url"`
+}
+
+// Config is the shape of the optional `-config` YAML file: a list of
+// targets to scrape, e.g.
+//
+//	targets:
+//	  - name: spot
+//	    type: spot
+//	  - name: umfutures
+//	    type: umfutures
+//	  - name: cmfutures
+//	    type: cmfutures
+type Config struct {
+	Targets []Target `yaml:"targets"`
+}
+
+// LoadConfig reads and parses a YAML config file in the Config shape.
+func LoadConfig(path string) (Config, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("reading config: %w", err)
+	}
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return Config{}, fmt.Errorf("parsing config: %w", err)
+	}
+	return cfg, nil
+}
+
+// TargetFlag implements flag.Value so callers can accept repeated
+// `-target name=...,type=...,url=...` flags and collect them into a []Target.
+type TargetFlag struct {
+	Targets *[]Target
+}
+
+func (f *TargetFlag) String() string {
+	return ""
+}
+
+func (f *TargetFlag) Set(value string) error {
+	t := Target{}
+	for _, field := range strings.Split(value, ",") {
+		kv := strings.SplitN(field, "=", 2)
+		if len(kv) != 2 {
+			return fmt.Errorf("invalid -target field %q, want key=value", field)
+		}
+		switch strings.TrimSpace(kv[0]) {
+		case "name":
+			t.Name = strings.TrimSpace(kv[1])
+		case "type":
+			t.Type = marketType(strings.TrimSpace(kv[1]))
+		case "url":
+			t.URL = strings.TrimSpace(kv[1])
+		default:
+			return fmt.Errorf("invalid -target field %q", kv[0])
+		}
+	}
+	if t.Name == "" {
+		return fmt.Errorf("-target %q is missing name=", value)
+	}
+	if t.Type == "" {
+		t.Type = SpotMarket
+	}
+	*f.Targets = append(*f.Targets, t)
+	return nil
+}
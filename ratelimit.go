@@ -0,0 +1,57 @@
+package binanceweight
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// observeResponse records the rate-limit headers of resp and, per Binance's
+// documented rate-limit protocol, handles HTTP 429 (Too Many Requests) and
+// 418 (IP banned) by recording the Retry-After window and arming a backoff
+// so RequestWeight/FetchSignedWeight stop probing until it elapses.
+func (c *WeightCollector) observeResponse(resp *http.Response) {
+	c.parseHeaders(resp.Header)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.lastStatus = float64(resp.StatusCode)
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		c.rateLimitedCount++
+		retryAfter := parseRetryAfter(resp.Header)
+		c.retryAfterSeconds = retryAfter
+		c.backoffUntil = time.Now().Add(time.Duration(retryAfter * float64(time.Second)))
+	case http.StatusTeapot:
+		c.bannedCount++
+		retryAfter := parseRetryAfter(resp.Header)
+		c.retryAfterSeconds = retryAfter
+		until := time.Now().Add(time.Duration(retryAfter * float64(time.Second)))
+		c.backoffUntil = until
+		c.banUntilTimestamp = float64(until.Unix())
+	}
+}
+
+// parseRetryAfter reads the `Retry-After` header as a number of seconds, per
+// Binance's rate-limit documentation. Returns 0 if absent or unparsable.
+func parseRetryAfter(header http.Header) float64 {
+	value := header.Get("Retry-After")
+	if value == "" {
+		return 0
+	}
+	seconds, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0
+	}
+	return seconds
+}
+
+// backoff reports whether the collector is currently within a backoff
+// window armed by a prior 429/418 response, and the time it ends.
+func (c *WeightCollector) backoff() (bool, time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return time.Now().Before(c.backoffUntil), c.backoffUntil
+}
@@ -0,0 +1,61 @@
+package binanceweight
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  float64
+	}{
+		{"absent", "", 0},
+		{"integer seconds", "5", 5},
+		{"fractional seconds", "1.5", 1.5},
+		{"unparsable", "Mon, 01 Jan 2024 00:00:00 GMT", 0},
+	}
+
+	for _, tt := range tests {
+		header := http.Header{}
+		if tt.value != "" {
+			header.Set("Retry-After", tt.value)
+		}
+		if got := parseRetryAfter(header); got != tt.want {
+			t.Errorf("%s: parseRetryAfter() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	c, err := NewCollector(Target{Name: "spot", Type: SpotMarket}, false)
+	if err != nil {
+		t.Fatalf("NewCollector: %v", err)
+	}
+
+	if wait, _ := c.backoff(); wait {
+		t.Fatalf("backoff() = true before any backoff was armed, want false")
+	}
+
+	c.mu.Lock()
+	c.backoffUntil = time.Now().Add(time.Minute)
+	c.mu.Unlock()
+
+	wait, until := c.backoff()
+	if !wait {
+		t.Fatalf("backoff() = false with backoffUntil in the future, want true")
+	}
+	if until != c.backoffUntil {
+		t.Errorf("backoff() until = %v, want %v", until, c.backoffUntil)
+	}
+
+	c.mu.Lock()
+	c.backoffUntil = time.Now().Add(-time.Minute)
+	c.mu.Unlock()
+
+	if wait, _ := c.backoff(); wait {
+		t.Fatalf("backoff() = true with backoffUntil in the past, want false")
+	}
+}
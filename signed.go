@@ -0,0 +1,60 @@
+package binanceweight
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// FetchSignedWeight calls the target's account endpoint (HMAC-SHA256 signed
+// with apiKey/apiSecret, Binance's standard SIGNED request scheme) purely to
+// read the rate-limit headers off the response. Unlike RequestWeight, this
+// hits an authenticated, weight-heavy endpoint, so the reported weight
+// reflects the account's own rate-limit window rather than the shared IP
+// window.
+func (c *WeightCollector) FetchSignedWeight(apiKey, apiSecret string) error {
+	if apiKey == "" || apiSecret == "" {
+		return fmt.Errorf("%s: signed polling requires both an API key and secret", c.market)
+	}
+
+	if wait, until := c.backoff(); wait {
+		return fmt.Errorf("%s: skipping signed poll, backing off until %s", c.market, until)
+	}
+
+	query := url.Values{}
+	query.Set("timestamp", fmt.Sprintf("%d", time.Now().UnixMilli()))
+	query.Set("signature", sign(apiSecret, query.Encode()))
+
+	req, err := http.NewRequest("GET", c.binanceEndpoint+c.accountPath+"?"+query.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-MBX-APIKEY", apiKey)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		c.mu.Lock()
+		c.isUp = 0
+		c.mu.Unlock()
+		return err
+	}
+	defer resp.Body.Close()
+
+	c.mu.Lock()
+	c.isUp = 1
+	c.mu.Unlock()
+	c.observeResponse(resp)
+	return nil
+}
+
+// sign computes the hex-encoded HMAC-SHA256 signature Binance expects as the
+// `signature` parameter of a SIGNED endpoint, over the given query string.
+func sign(apiSecret, query string) string {
+	mac := hmac.New(sha256.New, []byte(apiSecret))
+	mac.Write([]byte(query))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,33 @@
+package binanceweight
+
+import "testing"
+
+func TestSign(t *testing.T) {
+	tests := []struct {
+		name      string
+		apiSecret string
+		query     string
+		want      string
+	}{
+		// Expected values from Binance's own signed-endpoint documentation
+		// example.
+		{
+			name:      "docs example",
+			apiSecret: "NhqPtmdSJYdKjVHjA7PZj4Mge3R5YNiP1e3UZjInClVN65XAbvqqM6A7H5fATj0j",
+			query:     "symbol=LTCBTC&side=BUY&type=LIMIT&timeInForce=GTC&quantity=1&price=0.1&recvWindow=5000&timestamp=1499827319559",
+			want:      "c8db56825ae71d6d79447849e617115f4a920fa2acdcab2b053c4b2838bd6b71",
+		},
+		{
+			name:      "empty query",
+			apiSecret: "secret",
+			query:     "",
+			want:      "f9e66e179b6747ae54108f82f8ade8b3c25d76fd30afde6c395822c530196169",
+		},
+	}
+
+	for _, tt := range tests {
+		if got := sign(tt.apiSecret, tt.query); got != tt.want {
+			t.Errorf("%s: sign() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
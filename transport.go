@@ -0,0 +1,48 @@
+package binanceweight
+
+import "net/http"
+
+// WeightTransport wraps an http.RoundTripper so that every real response
+// passing through it has its X-MBX-USED-WEIGHT-* / X-MBX-ORDER-COUNT-*
+// headers fed into a WeightCollector, without issuing any synthetic
+// requests of its own. Drop it into an existing http.Client to get passive,
+// zero-overhead rate-limit metrics for traffic the program is already
+// making:
+//
+//	client := &http.Client{
+//		Transport: binanceweight.NewWeightTransport(nil, collector),
+//	}
+type WeightTransport struct {
+	// Base is the underlying RoundTripper used to perform the request.
+	// http.DefaultTransport is used if nil.
+	Base      http.RoundTripper
+	collector *WeightCollector
+}
+
+// NewWeightTransport returns a WeightTransport that feeds rate-limit headers
+// observed on every response into collector. base may be nil, in which case
+// http.DefaultTransport is used.
+func NewWeightTransport(base http.RoundTripper, collector *WeightCollector) *WeightTransport {
+	return &WeightTransport{Base: base, collector: collector}
+}
+
+func (t *WeightTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	resp, err := base.RoundTrip(req)
+	if err != nil {
+		t.collector.mu.Lock()
+		t.collector.isUp = 0
+		t.collector.mu.Unlock()
+		return resp, err
+	}
+
+	t.collector.mu.Lock()
+	t.collector.isUp = 1
+	t.collector.mu.Unlock()
+	t.collector.observeResponse(resp)
+	return resp, nil
+}
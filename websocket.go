@@ -0,0 +1,366 @@
+package binanceweight
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	wsMinBackoff = 1 * time.Second
+	wsMaxBackoff = 1 * time.Minute
+	listenKeyTTL = 30 * time.Minute
+)
+
+// WSCollector complements WeightCollector with a "is my market-data pipe
+// healthy" signal: it holds a combined WebSocket stream open (public
+// streams, plus the account's user data stream when API credentials are
+// configured) and exposes connection health as Prometheus metrics, rather
+// than weight itself.
+type WSCollector struct {
+	connectedDesc    *prometheus.Desc
+	reconnectsDesc   *prometheus.Desc
+	messagesDesc     *prometheus.Desc
+	listenKeyAgeDesc *prometheus.Desc
+
+	market        string
+	restEndpoint  string
+	userDataPath  string
+	wsEndpoint    string
+	apiKey        string
+	publicStreams []string
+
+	// listenKeyReady is signaled once, the first time manageListenKey
+	// successfully creates a listenKey, so a connectAndRead already running
+	// without the user data stream can force a reconnect to pick it up.
+	listenKeyReady chan struct{}
+
+	mu                 sync.Mutex
+	connected          float64
+	reconnectsTotal    float64
+	messagesByStream   map[string]float64
+	listenKey          string
+	listenKeyCreatedAt time.Time
+}
+
+// NewWSCollector builds a WSCollector for target. publicStreams are
+// lightweight public stream names (e.g. "btcusdt@trade") to keep a live
+// connection open even without API credentials; apiKey, when set, also
+// opens the account's user data stream.
+func NewWSCollector(target Target, apiKey string, publicStreams []string) (*WSCollector, error) {
+	paths, ok := defaultMarketPaths[target.Type]
+	if !ok {
+		return nil, fmt.Errorf("target %q: unknown type %q", target.Name, target.Type)
+	}
+
+	restEndpoint := target.URL
+	if restEndpoint == "" {
+		restEndpoint = paths.endpoint
+	}
+
+	return &WSCollector{
+		market:           target.Name,
+		restEndpoint:     restEndpoint,
+		userDataPath:     paths.userDataStream,
+		wsEndpoint:       paths.wsEndpoint,
+		apiKey:           apiKey,
+		publicStreams:    publicStreams,
+		messagesByStream: map[string]float64{},
+		listenKeyReady:   make(chan struct{}, 1),
+		connectedDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "ws", "connected"),
+			"Whether the market-data WebSocket is currently connected.",
+			[]string{"market"}, nil,
+		),
+		reconnectsDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "ws", "reconnects_total"),
+			"Number of times the market-data WebSocket has had to reconnect.",
+			[]string{"market"}, nil,
+		),
+		messagesDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "ws", "messages_total"),
+			"Number of WebSocket messages received, by stream.",
+			[]string{"market", "stream"}, nil,
+		),
+		listenKeyAgeDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "listenkey", "age_seconds"),
+			"Seconds since the current user data stream listenKey was created or last kept alive. 0 until a listenKey has been created.",
+			[]string{"market"}, nil,
+		),
+	}, nil
+}
+
+func (c *WSCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.connectedDesc
+	ch <- c.reconnectsDesc
+	ch <- c.messagesDesc
+	ch <- c.listenKeyAgeDesc
+}
+
+func (c *WSCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ch <- prometheus.MustNewConstMetric(c.connectedDesc, prometheus.GaugeValue, c.connected, c.market)
+	ch <- prometheus.MustNewConstMetric(c.reconnectsDesc, prometheus.CounterValue, c.reconnectsTotal, c.market)
+	for stream, count := range c.messagesByStream {
+		ch <- prometheus.MustNewConstMetric(c.messagesDesc, prometheus.CounterValue, count, c.market, stream)
+	}
+
+	age := 0.0
+	if c.listenKey != "" {
+		age = time.Since(c.listenKeyCreatedAt).Seconds()
+	}
+	ch <- prometheus.MustNewConstMetric(c.listenKeyAgeDesc, prometheus.GaugeValue, age, c.market)
+}
+
+// Run opens the combined WebSocket stream and blocks, reconnecting with
+// exponential backoff on drop, until ctx is cancelled. If an API key was
+// configured, it also creates and keeps alive a user data stream listenKey
+// on a 30-minute schedule for the lifetime of the call. A listenKey failure
+// is retried independently and never prevents the public-stream connection
+// from being established.
+func (c *WSCollector) Run(ctx context.Context) error {
+	if c.apiKey != "" {
+		go c.manageListenKey(ctx)
+	}
+
+	backoff := wsMinBackoff
+	for {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		if err := c.connectAndRead(ctx); err != nil {
+			c.mu.Lock()
+			wasConnected := c.connected == 1
+			c.connected = 0
+			c.reconnectsTotal++
+			c.mu.Unlock()
+
+			// A drop after a successful dial starts a fresh backoff sequence.
+			if wasConnected {
+				backoff = wsMinBackoff
+			}
+
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(backoff):
+			}
+
+			backoff *= 2
+			if backoff > wsMaxBackoff {
+				backoff = wsMaxBackoff
+			}
+			continue
+		}
+
+		backoff = wsMinBackoff
+	}
+}
+
+// streamEnvelope is the shape of each message on a combined stream
+// (`/stream?streams=...`). Only the originating stream name is decoded; the
+// data payload is ignored.
+type streamEnvelope struct {
+	Stream string `json:"stream"`
+}
+
+func (c *WSCollector) connectAndRead(ctx context.Context) error {
+	streams := append([]string{}, c.publicStreams...)
+
+	c.mu.Lock()
+	hasListenKey := c.listenKey != ""
+	if hasListenKey {
+		streams = append(streams, c.listenKey)
+	}
+	c.mu.Unlock()
+
+	if len(streams) == 0 {
+		return fmt.Errorf("%s: no public streams configured and no listenKey available", c.market)
+	}
+
+	wsURL := c.wsEndpoint + "/stream?streams=" + strings.Join(streams, "/")
+
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, wsURL, nil)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	c.mu.Lock()
+	c.connected = 1
+	c.mu.Unlock()
+
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+
+	// If this connection was dialed before a listenKey existed, force a
+	// reconnect the moment one becomes available so the user data stream
+	// doesn't stay unsubscribed for the rest of this connection's life.
+	if !hasListenKey {
+		go func() {
+			select {
+			case <-c.listenKeyReady:
+				conn.Close()
+			case <-done:
+			case <-ctx.Done():
+			}
+		}()
+	}
+
+	for {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			return err
+		}
+
+		var envelope streamEnvelope
+		if err := json.Unmarshal(message, &envelope); err != nil || envelope.Stream == "" {
+			continue
+		}
+
+		c.mu.Lock()
+		c.messagesByStream[envelope.Stream]++
+		c.mu.Unlock()
+	}
+}
+
+// manageListenKey creates the user data stream listenKey, retrying with
+// backoff until it succeeds or ctx is cancelled, then keeps it alive for the
+// lifetime of ctx. It runs independently of connectAndRead so a listenKey
+// failure never blocks or tears down the public-stream connection.
+func (c *WSCollector) manageListenKey(ctx context.Context) {
+	backoff := wsMinBackoff
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+
+		if err := c.createListenKey(); err != nil {
+			log.Printf("%s: creating listenKey: %v, retrying", c.market, err)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+
+			backoff *= 2
+			if backoff > wsMaxBackoff {
+				backoff = wsMaxBackoff
+			}
+			continue
+		}
+
+		break
+	}
+
+	// Wake up a connection that's already running without the user data
+	// stream, if there is one; non-blocking since nobody may be listening.
+	select {
+	case c.listenKeyReady <- struct{}{}:
+	default:
+	}
+
+	c.keepAliveListenKey(ctx)
+}
+
+// createListenKey opens a new user data stream and records its key.
+func (c *WSCollector) createListenKey() error {
+	req, err := http.NewRequest("POST", c.restEndpoint+c.userDataPath, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-MBX-APIKEY", c.apiKey)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, body)
+	}
+
+	var parsed struct {
+		ListenKey string `json:"listenKey"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	c.listenKey = parsed.ListenKey
+	c.listenKeyCreatedAt = time.Now()
+	c.mu.Unlock()
+	return nil
+}
+
+// keepAliveListenKey pings Binance to extend the current listenKey every
+// listenKeyTTL until ctx is cancelled, as required to keep a user data
+// stream alive past its first 60 minutes.
+func (c *WSCollector) keepAliveListenKey(ctx context.Context) {
+	ticker := time.NewTicker(listenKeyTTL)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := c.pingListenKey(); err != nil {
+				log.Printf("%s: listenKey keepalive failed, will retry next cycle: %v", c.market, err)
+			}
+		}
+	}
+}
+
+func (c *WSCollector) pingListenKey() error {
+	c.mu.Lock()
+	listenKey := c.listenKey
+	c.mu.Unlock()
+
+	query := url.Values{}
+	query.Set("listenKey", listenKey)
+
+	req, err := http.NewRequest("PUT", c.restEndpoint+c.userDataPath+"?"+query.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-MBX-APIKEY", c.apiKey)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, body)
+	}
+
+	c.mu.Lock()
+	c.listenKeyCreatedAt = time.Now()
+	c.mu.Unlock()
+	return nil
+}